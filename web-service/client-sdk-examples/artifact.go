@@ -0,0 +1,169 @@
+package main
+
+/*
+原型产物下载与离线使用
+
+CreatePrototype 拿到的只是一个 prototype_url，产物本身还在 Agent 侧（或其挂载的对象存储）。
+DownloadPrototype 把 PrototypeURL 指向的 HTML/CSS/JS 产物抓到本地目录（命中 internal_domain
+时改走集群内地址），返回的 Prototype 既能当 http.Handler 直接用 httptest.Server 起来预览，
+也能用 Zip 打包带走，闭环"拿到 URL"和"离线/CI 里能直接用这份产物"之间的落差。
+*/
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prototypeAssetNames 是 Agent 产物目录里按约定固定打包的文件，不存在的会被跳过
+var prototypeAssetNames = []string{"index.html", "style.css", "script.js"}
+
+// Prototype 是一次 DownloadPrototype 落盘后的本地产物
+type Prototype struct {
+	Dir   string   // 本地目录（缓存目录或 destDir 下的内容寻址子目录）
+	Files []string // Dir 下实际下载到的产物文件，相对 Dir 的路径
+}
+
+// Handler 返回一个直接服务本地产物目录的 http.Handler，配合 httptest.NewServer 可以零配置预览
+func (p *Prototype) Handler() http.Handler {
+	return http.FileServer(http.Dir(p.Dir))
+}
+
+// Zip 把产物目录下的所有文件打包写入 w
+func (p *Prototype) Zip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	defer zw.Close()
+
+	for _, rel := range p.Files {
+		if err := writeZipEntry(zw, p.Dir, rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeZipEntry 把 dir/rel 这一个产物文件写成 zw 里的一个条目
+func writeZipEntry(zw *zip.Writer, dir, rel string) error {
+	f, err := os.Open(filepath.Join(dir, rel))
+	if err != nil {
+		return fmt.Errorf("打开产物文件失败: %w", err)
+	}
+	defer f.Close()
+
+	entry, err := zw.Create(filepath.ToSlash(rel))
+	if err != nil {
+		return fmt.Errorf("创建zip条目失败: %w", err)
+	}
+	if _, err := io.Copy(entry, f); err != nil {
+		return fmt.Errorf("写入zip条目失败: %w", err)
+	}
+	return nil
+}
+
+// DownloadPrototype 下载 resp 引用的原型产物到 destDir 下一个按 prototype_url 内容寻址的
+// 子目录。客户端配置了 WithPrototypeCache 时，指向同一个 prototype_url 的重复调用会直接
+// 返回缓存里已有的 Prototype，不会再次访问 Agent 或网络。
+func (c *AIAgentClient) DownloadPrototype(resp *DesignResponse, destDir string) (*Prototype, error) {
+	if resp.PrototypeURL == "" {
+		return nil, fmt.Errorf("设计响应中没有 prototype_url")
+	}
+
+	key := prototypeCacheKey(resp.PrototypeURL)
+
+	if c.prototypeCache != nil {
+		if proto, ok := c.prototypeCache.Get(key); ok {
+			return proto, nil
+		}
+	}
+
+	proto, err := c.downloadPrototypeFiles(resp, filepath.Join(destDir, key))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.prototypeCache != nil {
+		c.prototypeCache.Put(key, proto)
+	}
+	return proto, nil
+}
+
+// downloadPrototypeFiles 把 resp 引用的产物抓取到 dir 下
+func (c *AIAgentClient) downloadPrototypeFiles(resp *DesignResponse, dir string) (*Prototype, error) {
+	base, err := prototypeBaseURL(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建本地产物目录失败: %w", err)
+	}
+
+	var files []string
+	for _, name := range prototypeAssetNames {
+		assetURL := strings.TrimRight(base, "/") + "/" + name
+		ok, err := c.downloadAsset(assetURL, filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			files = append(files, name)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("未能从 %s 下载到任何产物文件", base)
+	}
+
+	return &Prototype{Dir: dir, Files: files}, nil
+}
+
+// downloadAsset 下载单个产物文件到 destPath，远端返回 404 时视为该文件不存在，返回 (false, nil)
+func (c *AIAgentClient) downloadAsset(assetURL, destPath string) (bool, error) {
+	resp, err := c.Client.Get(assetURL)
+	if err != nil {
+		return false, fmt.Errorf("下载产物文件失败 %s: %w", assetURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode >= 400 {
+		return false, fmt.Errorf("下载产物文件失败 %s: HTTP错误 [%d]", assetURL, resp.StatusCode)
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return false, fmt.Errorf("创建本地产物文件失败: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return false, fmt.Errorf("写入本地产物文件失败: %w", err)
+	}
+	return true, nil
+}
+
+// prototypeBaseURL 计算产物所在的目录 URL：PrototypeURL 若指向具体文件（带扩展名）则取其所在目录，
+// 否则原样当作目录使用；resp.InternalDomain 非空时改写 host，走集群内地址而不是对外地址
+func prototypeBaseURL(resp *DesignResponse) (string, error) {
+	u, err := url.Parse(resp.PrototypeURL)
+	if err != nil {
+		return "", fmt.Errorf("解析prototype_url失败: %w", err)
+	}
+
+	if resp.InternalDomain != "" {
+		u.Host = resp.InternalDomain
+	}
+
+	if ext := filepath.Ext(u.Path); ext != "" {
+		u.Path = filepath.ToSlash(filepath.Dir(u.Path))
+	}
+
+	return u.String(), nil
+}
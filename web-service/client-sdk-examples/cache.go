@@ -0,0 +1,111 @@
+package main
+
+/*
+原型产物本地缓存 (TTL + LRU)
+
+PrototypeCache 把 DownloadPrototype 落盘的 Prototype 按 prototype_url 的哈希缓存起来：
+命中时直接返回已有的本地文件，不用重新下载也不用再打一次 Agent；条目超过 TTL 在下次访问时
+失效，数量超过 MaxEntries 按最久未使用淘汰，淘汰/失效时一并删除对应的本地缓存目录，避免
+磁盘无限增长。
+*/
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+)
+
+// prototypeCacheKey 用 prototype_url 的哈希作为内容寻址缓存的 key。不用 requirement+style
+// 是因为一个 DesignResponse 不一定来自 CreatePrototype —— 也可能是从 CreatePrototypeStream
+// 的 prototype_ready 事件解出来的，或者从 history.Record 里重建的，这些路径都没有原始
+// requirement/style；prototype_url 是 DesignResponse 唯一总能保证有值、且能区分不同产物的字段。
+func prototypeCacheKey(prototypeURL string) string {
+	sum := sha256.Sum256([]byte(prototypeURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// prototypeCacheEntry 是 PrototypeCache 内部 LRU 链表上的一个节点
+type prototypeCacheEntry struct {
+	key       string
+	proto     *Prototype
+	expiresAt time.Time
+}
+
+// PrototypeCache 是 DownloadPrototype 的本地产物缓存，线程安全
+type PrototypeCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	lru     *list.List // front = 最近使用
+	entries map[string]*list.Element
+}
+
+// NewPrototypeCache 创建一个 PrototypeCache。ttl<=0 表示条目永不过期，
+// maxEntries<=0 表示不限制容量（只按 TTL 过期）
+func NewPrototypeCache(ttl time.Duration, maxEntries int) *PrototypeCache {
+	return &PrototypeCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+// Get 查找 key 对应的缓存产物；未命中或已过期返回 false，过期条目会被顺带清理掉
+func (c *PrototypeCache) Get(key string) (*Prototype, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*prototypeCacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expiresAt) {
+		c.removeLocked(el)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	return entry.proto, true
+}
+
+// Put 写入一条缓存记录，容量超出 MaxEntries 时淘汰最久未使用的条目
+func (c *PrototypeCache) Put(key string, proto *Prototype) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*prototypeCacheEntry)
+		entry.proto, entry.expiresAt = proto, expiresAt
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	el := c.lru.PushFront(&prototypeCacheEntry{key: key, proto: proto, expiresAt: expiresAt})
+	c.entries[key] = el
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.removeLocked(c.lru.Back())
+	}
+}
+
+// removeLocked 从 lru/entries 中移除一条记录并清理其本地缓存目录；调用方需持有 c.mu
+func (c *PrototypeCache) removeLocked(el *list.Element) {
+	entry := el.Value.(*prototypeCacheEntry)
+	delete(c.entries, entry.key)
+	c.lru.Remove(el)
+	if entry.proto != nil {
+		_ = os.RemoveAll(entry.proto.Dir)
+	}
+}
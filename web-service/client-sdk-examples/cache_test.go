@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestPrototype(t *testing.T, dir string) *Prototype {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("创建测试目录失败: %v", err)
+	}
+	return &Prototype{Dir: dir, Files: []string{"index.html"}}
+}
+
+func TestPrototypeCacheKeyIsStableAndDistinct(t *testing.T) {
+	if prototypeCacheKey("http://a/x") != prototypeCacheKey("http://a/x") {
+		t.Fatal("同一个prototype_url应该产生相同的key")
+	}
+	if prototypeCacheKey("http://a/x") == prototypeCacheKey("http://a/y") {
+		t.Fatal("不同的prototype_url不应该产生相同的key")
+	}
+}
+
+func TestPrototypeCacheGetPutHit(t *testing.T) {
+	c := NewPrototypeCache(time.Hour, 0)
+	proto := newTestPrototype(t, filepath.Join(t.TempDir(), "p1"))
+
+	c.Put("key1", proto)
+	got, ok := c.Get("key1")
+	if !ok || got != proto {
+		t.Fatalf("应该命中刚写入的缓存，got %+v, ok=%v", got, ok)
+	}
+
+	if _, ok := c.Get("没有的key"); ok {
+		t.Fatal("没写过的key不应该命中")
+	}
+}
+
+func TestPrototypeCacheTTLExpires(t *testing.T) {
+	c := NewPrototypeCache(10*time.Millisecond, 0)
+	dir := filepath.Join(t.TempDir(), "p1")
+	proto := newTestPrototype(t, dir)
+
+	c.Put("key1", proto)
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := c.Get("key1"); ok {
+		t.Fatal("超过TTL的条目应该失效")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatal("过期条目应该清理掉对应的本地目录")
+	}
+}
+
+func TestPrototypeCacheLRUEviction(t *testing.T) {
+	c := NewPrototypeCache(0, 2)
+	dirA := filepath.Join(t.TempDir(), "a")
+	dirB := filepath.Join(t.TempDir(), "b")
+	dirC := filepath.Join(t.TempDir(), "c")
+
+	protoA := newTestPrototype(t, dirA)
+	protoB := newTestPrototype(t, dirB)
+	protoC := newTestPrototype(t, dirC)
+
+	c.Put("a", protoA)
+	c.Put("b", protoB)
+
+	// 访问一次a，让它变成最近使用，这样淘汰时应该轮到b
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a应该还在缓存里")
+	}
+
+	c.Put("c", protoC) // 超过容量2，应该淘汰最久未使用的b
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("b是最久未使用的条目，应该被淘汰")
+	}
+	if _, err := os.Stat(dirB); !os.IsNotExist(err) {
+		t.Fatal("被淘汰的条目应该清理掉本地目录")
+	}
+
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a最近访问过，不应该被淘汰")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("c是刚写入的，不应该被淘汰")
+	}
+}
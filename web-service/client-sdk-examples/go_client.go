@@ -16,18 +16,35 @@ AI Agent Web Service - Go客户端SDK
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/ccw33/Agents/web-service/client-sdk-examples/history"
 )
 
 // AIAgentClient AI Agent服务客户端
 type AIAgentClient struct {
-	BaseURL string
-	Client  *http.Client
+	BaseURL  string
+	Client   *http.Client
+	Resolver Resolver
+
+	history *history.Manager
+
+	prototypeCache *PrototypeCache
+}
+
+// History 返回客户端的调用历史管理器；客户端未通过 WithHistory 配置时返回 nil
+func (c *AIAgentClient) History() *history.Manager {
+	return c.history
 }
 
 // HealthResponse 健康检查响应
@@ -93,20 +110,82 @@ func NewAIAgentClient() *AIAgentClient {
 	}
 }
 
-// NewAIAgentClientWithConfig 使用自定义配置创建客户端
-func NewAIAgentClientWithConfig(baseURL string, timeout time.Duration) *AIAgentClient {
-	return &AIAgentClient{
-		BaseURL: baseURL,
-		Client: &http.Client{
-			Timeout: timeout,
-		},
+// NewAIAgentClientWithConfig 使用自定义配置创建客户端，通过 ClientOption 组合重试、限流、
+// 熔断、鉴权、链路追踪等中间件。不传 opts 时行为等价于默认 30s 超时、无中间件的客户端。
+func NewAIAgentClientWithConfig(baseURL string, opts ...ClientOption) *AIAgentClient {
+	cfg := &clientConfig{timeout: 30 * time.Second}
+	client := &AIAgentClient{BaseURL: baseURL}
+
+	for _, opt := range opts {
+		opt(client, cfg)
+	}
+
+	client.Client = &http.Client{
+		Timeout:   cfg.timeout,
+		Transport: chainMiddlewares(http.DefaultTransport, cfg.middlewares...),
+	}
+
+	if cfg.prototypeCache != nil {
+		client.prototypeCache = cfg.prototypeCache
 	}
+
+	if cfg.historyStore != nil {
+		client.history = history.NewManager(cfg.historyStore, func(ctx context.Context, requirement, style string) (*history.Record, error) {
+			result, err := client.CreatePrototype(requirement, style)
+			if err != nil {
+				return nil, err
+			}
+			return &history.Record{
+				Requirement:  requirement,
+				Style:        style,
+				Status:       result.Status,
+				Success:      result.Success,
+				Message:      result.Message,
+				PrototypeURL: result.PrototypeURL,
+			}, nil
+		})
+	}
+
+	return client
 }
 
-// makeRequest 发送HTTP请求
+// makeRequest 发送HTTP请求。当客户端配置了 Resolver 时，每次请求都会重新解析 base URL，
+// 这样 Service 背后的 Pod 重启或扩缩容不会影响长连接调用方；遇到连接错误时会触发一次重新解析重试。
 func (c *AIAgentClient) makeRequest(method, endpoint string, body interface{}) ([]byte, error) {
-	url := c.BaseURL + endpoint
-	
+	baseURL, err := c.resolveBaseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := c.doRequest(baseURL, method, endpoint, body)
+	// 只有 http.Client 返回的传输层错误（*url.Error）才算连接错误，HTTP 错误响应不重试
+	var connErr *url.Error
+	if err != nil && c.Resolver != nil && errors.As(err, &connErr) {
+		// base URL 可能已经失效（Pod 重启/滚动更新），重新解析一次再试
+		retryURL, resolveErr := c.resolveBaseURL()
+		if resolveErr == nil {
+			return c.doRequest(retryURL, method, endpoint, body)
+		}
+	}
+	return respBody, err
+}
+
+// resolveBaseURL 返回本次请求应使用的 base URL：配置了 Resolver 则每次重新解析，否则使用固定 BaseURL
+func (c *AIAgentClient) resolveBaseURL() (string, error) {
+	if c.Resolver == nil {
+		return c.BaseURL, nil
+	}
+	resolved, err := c.Resolver.Resolve(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("解析服务地址失败: %w", err)
+	}
+	return resolved, nil
+}
+
+// doRequest 向指定 base URL 发送一次HTTP请求
+func (c *AIAgentClient) doRequest(baseURL, method, endpoint string, body interface{}) ([]byte, error) {
+	url := baseURL + endpoint
+
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -192,30 +271,71 @@ func (c *AIAgentClient) CheckAgentHealth() (*AgentHealthResponse, error) {
 	return &agentHealth, nil
 }
 
-// CreatePrototype 创建原型设计
+// CreatePrototype 创建原型设计。当客户端通过 WithHistory 配置了历史记录后，
+// 无论成功还是失败都会把这次调用写入 History()。
 func (c *AIAgentClient) CreatePrototype(requirement, style string) (*DesignResponse, error) {
 	if style == "" {
 		style = "现代简约"
 	}
-	
+
 	reqData := DesignRequest{
 		Requirement: requirement,
 		Style:       style,
 	}
-	
+
+	start := time.Now()
 	respBody, err := c.makeRequest("POST", "/api/v1/prototype_design/design", reqData)
+	latency := time.Since(start)
 	if err != nil {
+		c.recordHistory(requirement, style, nil, latency, err)
 		return nil, err
 	}
-	
+
 	var result DesignResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("解析设计响应失败: %w", err)
+		wrapped := fmt.Errorf("解析设计响应失败: %w", err)
+		c.recordHistory(requirement, style, nil, latency, wrapped)
+		return nil, wrapped
 	}
-	
+	c.recordHistory(requirement, style, &result, latency, nil)
 	return &result, nil
 }
 
+// recordHistory 把一次 CreatePrototype 调用写入 History()，未配置 History 时是no-op
+func (c *AIAgentClient) recordHistory(requirement, style string, result *DesignResponse, latency time.Duration, callErr error) {
+	if c.history == nil {
+		return
+	}
+
+	record := history.Record{
+		ID:          newHistoryID(),
+		Requirement: requirement,
+		Style:       style,
+		LatencyMs:   latency.Milliseconds(),
+		CreatedAt:   time.Now(),
+	}
+	if callErr != nil {
+		record.Status = "error"
+		record.Message = callErr.Error()
+	} else {
+		record.Status = result.Status
+		record.Success = result.Success
+		record.Message = result.Message
+		record.PrototypeURL = result.PrototypeURL
+	}
+
+	if err := c.history.Record(context.Background(), record); err != nil {
+		log.Printf("写入调用历史失败: %v", err)
+	}
+}
+
+// newHistoryID 生成一个随机的历史记录ID
+func newHistoryID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
 // IsHealthy 检查服务是否健康
 func (c *AIAgentClient) IsHealthy() bool {
 	health, err := c.HealthCheck()
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// GormStore 是基于 GORM 的持久化 Store 实现，支持 SQLite 与 MySQL
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewSQLiteStore 打开（或创建）一个 SQLite 文件作为历史记录存储
+func NewSQLiteStore(path string) (*GormStore, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("打开sqlite失败: %w", err)
+	}
+	return newGormStore(db)
+}
+
+// NewMySQLStore 使用标准 DSN 连接 MySQL 作为历史记录存储
+func NewMySQLStore(dsn string) (*GormStore, error) {
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("连接mysql失败: %w", err)
+	}
+	return newGormStore(db)
+}
+
+func newGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		return nil, fmt.Errorf("自动迁移history表失败: %w", err)
+	}
+	return &GormStore{db: db}, nil
+}
+
+func (s *GormStore) Save(ctx context.Context, record Record) error {
+	return s.db.WithContext(ctx).Save(&record).Error
+}
+
+func (s *GormStore) Get(ctx context.Context, id string) (*Record, error) {
+	var record Record
+	err := s.db.WithContext(ctx).First(&record, "id = ?", id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *GormStore) List(ctx context.Context, filter Filter, page, limit int) ([]Record, int64, error) {
+	query := s.db.WithContext(ctx).Model(&Record{})
+
+	if filter.RequirementContains != "" {
+		// 跟 Filter.matches（MemoryStore.List 使用的过滤逻辑）保持一致的大小写不敏感语义，不依赖后端的排序规则
+		query = query.Where("LOWER(requirement) LIKE LOWER(?)", "%"+filter.RequirementContains+"%")
+	}
+	if filter.Style != "" {
+		query = query.Where("style = ?", filter.Style)
+	}
+	if filter.UserTag != "" {
+		query = query.Where("user_tag = ?", filter.UserTag)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit > 0 {
+		query = query.Offset((page - 1) * limit).Limit(limit)
+	}
+
+	var records []Record
+	if err := query.Order("created_at desc").Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+func (s *GormStore) Delete(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Delete(&Record{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package history
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReplayFunc 重新执行一次历史记录里的原始请求，由持有 AIAgentClient 的调用方提供，
+// 避免本包反向依赖 main 包
+type ReplayFunc func(ctx context.Context, requirement, style string) (*Record, error)
+
+// Manager 是 client.History() 返回的门面，组合了底层 Store 与重放回调
+type Manager struct {
+	store  Store
+	replay ReplayFunc
+}
+
+// NewManager 创建一个 Manager，store 为 nil 时会使用 MemoryStore
+func NewManager(store Store, replay ReplayFunc) *Manager {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Manager{store: store, replay: replay}
+}
+
+// Record 把一次调用的结果写入底层 Store，调用方（AIAgentClient.CreatePrototype）在
+// 每次请求完成后调用它，无论成功还是失败
+func (m *Manager) Record(ctx context.Context, record Record) error {
+	return m.store.Save(ctx, record)
+}
+
+// List 按过滤条件分页查询历史记录，page 从 1 开始，limit<=0 表示不分页
+func (m *Manager) List(ctx context.Context, filter Filter, page, limit int) ([]Record, int64, error) {
+	return m.store.List(ctx, filter, page, limit)
+}
+
+// Get 按 ID 查询单条历史记录
+func (m *Manager) Get(ctx context.Context, id string) (*Record, error) {
+	return m.store.Get(ctx, id)
+}
+
+// Delete 按 ID 删除一条历史记录
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	return m.store.Delete(ctx, id)
+}
+
+// Replay 读取一条历史记录，使用其原始 requirement/style 重新发起一次设计请求。
+// Manager 未通过 WithHistory 构造（例如直接 NewManager(store, nil) 用于测试）时
+// 没有可用的重放回调，返回 error 而不是 panic。
+func (m *Manager) Replay(ctx context.Context, id string) (*Record, error) {
+	if m.replay == nil {
+		return nil, fmt.Errorf("history: replay not configured")
+	}
+
+	record, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return m.replay(ctx, record.Requirement, record.Style)
+}
@@ -0,0 +1,41 @@
+package history
+
+import (
+	"context"
+	"testing"
+)
+
+func TestManagerReplayWithoutReplayFuncReturnsError(t *testing.T) {
+	m := NewManager(nil, nil)
+	ctx := context.Background()
+
+	if err := m.Record(ctx, Record{ID: "1", Requirement: "用户管理界面"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if _, err := m.Replay(ctx, "1"); err == nil {
+		t.Fatal("没有配置replay回调时，Replay应该返回error而不是panic")
+	}
+}
+
+func TestManagerReplayCallsReplayFunc(t *testing.T) {
+	var gotRequirement, gotStyle string
+	replay := func(ctx context.Context, requirement, style string) (*Record, error) {
+		gotRequirement, gotStyle = requirement, style
+		return &Record{ID: "replayed", Requirement: requirement, Style: style}, nil
+	}
+
+	m := NewManager(nil, replay)
+	ctx := context.Background()
+	if err := m.Record(ctx, Record{ID: "1", Requirement: "用户管理界面", Style: "现代简约"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	record, err := m.Replay(ctx, "1")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if record.ID != "replayed" || gotRequirement != "用户管理界面" || gotStyle != "现代简约" {
+		t.Fatalf("Replay没有用原始requirement/style调用回调: %+v", record)
+	}
+}
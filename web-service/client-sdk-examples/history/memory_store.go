@@ -0,0 +1,83 @@
+package history
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore 是一个进程内的 Store 实现，主要用于测试以及不需要持久化的短生命周期场景
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+// NewMemoryStore 创建一个空的内存 Store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ID] = record
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &r, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, filter Filter, page, limit int) ([]Record, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []Record
+	for _, r := range s.records {
+		if filter.matches(r) {
+			matched = append(matched, r)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].CreatedAt.After(matched[j].CreatedAt) })
+
+	total := int64(len(matched))
+	return paginate(matched, page, limit), total, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.records, id)
+	return nil
+}
+
+// paginate 对已排序好的记录切出第 page 页（从 1 开始），limit<=0 表示不分页
+func paginate(records []Record, page, limit int) []Record {
+	if limit <= 0 {
+		return records
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	start := (page - 1) * limit
+	if start >= len(records) {
+		return nil
+	}
+	end := start + limit
+	if end > len(records) {
+		end = len(records)
+	}
+	return records[start:end]
+}
@@ -0,0 +1,106 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFilterMatchesRequirementContainsIsCaseInsensitive(t *testing.T) {
+	r := Record{Requirement: "用户管理 Dashboard"}
+
+	f := Filter{RequirementContains: "dashboard"}
+	if !f.matches(r) {
+		t.Fatal("RequirementContains应该不区分大小写匹配")
+	}
+
+	f = Filter{RequirementContains: "不存在的内容"}
+	if f.matches(r) {
+		t.Fatal("不包含的子串不应该匹配")
+	}
+}
+
+func TestFilterMatchesAllFields(t *testing.T) {
+	now := time.Now()
+	r := Record{
+		Requirement: "用户管理界面",
+		Style:       "现代简约",
+		UserTag:     "team-a",
+		CreatedAt:   now,
+	}
+
+	cases := []struct {
+		name string
+		f    Filter
+		want bool
+	}{
+		{"style匹配", Filter{Style: "现代简约"}, true},
+		{"style不匹配", Filter{Style: "极简"}, false},
+		{"user_tag匹配", Filter{UserTag: "team-a"}, true},
+		{"user_tag不匹配", Filter{UserTag: "team-b"}, false},
+		{"From之前", Filter{From: now.Add(time.Hour)}, false},
+		{"From之后", Filter{From: now.Add(-time.Hour)}, true},
+		{"To之前", Filter{To: now.Add(-time.Hour)}, false},
+		{"To之后", Filter{To: now.Add(time.Hour)}, true},
+	}
+	for _, c := range cases {
+		if got := c.f.matches(r); got != c.want {
+			t.Errorf("%s: matches() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	records := make([]Record, 5)
+	for i := range records {
+		records[i] = Record{ID: string(rune('a' + i))}
+	}
+
+	if got := paginate(records, 1, 0); len(got) != 5 {
+		t.Fatalf("limit<=0应该不分页，got %d条", len(got))
+	}
+
+	page1 := paginate(records, 1, 2)
+	if len(page1) != 2 || page1[0].ID != "a" || page1[1].ID != "b" {
+		t.Fatalf("第1页(limit=2) = %+v", page1)
+	}
+
+	page3 := paginate(records, 3, 2)
+	if len(page3) != 1 || page3[0].ID != "e" {
+		t.Fatalf("第3页(limit=2) = %+v", page3)
+	}
+
+	pageBeyond := paginate(records, 10, 2)
+	if len(pageBeyond) != 0 {
+		t.Fatalf("超出范围的页应该返回空，got %+v", pageBeyond)
+	}
+}
+
+func TestMemoryStoreSaveGetDelete(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	record := Record{ID: "1", Requirement: "用户管理界面"}
+	if err := s.Save(ctx, record); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := s.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Requirement != record.Requirement {
+		t.Fatalf("Get返回的记录不一致: %+v", got)
+	}
+
+	if _, err := s.Get(ctx, "不存在"); err != ErrNotFound {
+		t.Fatalf("Get不存在的ID应该返回ErrNotFound，got %v", err)
+	}
+
+	if err := s.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(ctx, "1"); err != ErrNotFound {
+		t.Fatalf("重复Delete应该返回ErrNotFound，got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Package history 记录 AIAgentClient 每次 CreatePrototype 调用的请求/响应，
+// 提供可插拔的 Store（内存实现用于测试，GORM 实现用于持久化），
+// 并支持按条件分页查询与重放历史调用。
+package history
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 在按 ID 查找记录不存在时返回
+var ErrNotFound = errors.New("history: record not found")
+
+// Record 是一次 CreatePrototype 调用的完整记录
+type Record struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	Requirement  string    `json:"requirement"`
+	Style        string    `json:"style"`
+	Status       string    `json:"status"`
+	Success      bool      `json:"success"`
+	Message      string    `json:"message"`
+	PrototypeURL string    `json:"prototype_url"`
+	UserTag      string    `json:"user_tag"`
+	LatencyMs    int64     `json:"latency_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Filter 用于 List 查询时按字段缩小范围，零值字段表示不过滤
+type Filter struct {
+	RequirementContains string
+	Style               string
+	UserTag             string
+	From                time.Time
+	To                  time.Time
+}
+
+// Store 是历史记录的持久化接口，MemoryStore 与 GormStore 都实现了它
+type Store interface {
+	Save(ctx context.Context, record Record) error
+	Get(ctx context.Context, id string) (*Record, error)
+	List(ctx context.Context, filter Filter, page, limit int) ([]Record, int64, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// matches 判断一条记录是否满足过滤条件，GormStore 与 MemoryStore 共用同一套过滤语义
+func (f Filter) matches(r Record) bool {
+	if f.RequirementContains != "" && !strings.Contains(strings.ToLower(r.Requirement), strings.ToLower(f.RequirementContains)) {
+		return false
+	}
+	if f.Style != "" && r.Style != f.Style {
+		return false
+	}
+	if f.UserTag != "" && r.UserTag != f.UserTag {
+		return false
+	}
+	if !f.From.IsZero() && r.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && r.CreatedAt.After(f.To) {
+		return false
+	}
+	return true
+}
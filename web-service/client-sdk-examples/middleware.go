@@ -0,0 +1,410 @@
+package main
+
+/*
+中间件链 (Retry / 限流 / 熔断 / 鉴权 / 可观测性)
+
+makeRequest 过去直接调用 c.Client.Do，所有弹性策略（重试、限流、熔断……）都得由
+每个使用方自己实现。这里把发送逻辑收敛成一条 http.RoundTripper 中间件链，
+NewAIAgentClientWithConfig 通过 ClientOption 组合需要的中间件，SDK 从一个简单的
+HTTP 封装变成可以直接在生产环境使用的客户端。
+*/
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ccw33/Agents/web-service/client-sdk-examples/history"
+)
+
+// Middleware 把一个 RoundTripper 包装成另一个 RoundTripper，用于组成处理链
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// roundTripperFunc 让一个普通函数满足 http.RoundTripper 接口
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// chainMiddlewares 按顺序把中间件包裹在 base 之外，链中第一个中间件最先看到请求
+func chainMiddlewares(base http.RoundTripper, mws ...Middleware) http.RoundTripper {
+	rt := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt
+}
+
+// ClientOption 用于组合 NewAIAgentClientWithConfig 的可选配置
+type ClientOption func(*AIAgentClient, *clientConfig)
+
+// clientConfig 收集各个 ClientOption 产生的中间件，最终在构造完成时拼成一条链
+type clientConfig struct {
+	timeout      time.Duration
+	middlewares  []Middleware
+	historyStore   history.Store
+	prototypeCache *PrototypeCache
+}
+
+// WithClientTimeout 设置底层 http.Client 的超时时间
+func WithClientTimeout(timeout time.Duration) ClientOption {
+	return func(c *AIAgentClient, cfg *clientConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithResolverOption 为客户端配置一个 Resolver，取代固定的 BaseURL
+func WithResolverOption(resolver Resolver) ClientOption {
+	return func(c *AIAgentClient, cfg *clientConfig) {
+		c.Resolver = resolver
+	}
+}
+
+// WithMiddleware 追加一个或多个中间件到处理链末尾（越靠后添加，离底层 Transport 越近）
+func WithMiddleware(mws ...Middleware) ClientOption {
+	return func(c *AIAgentClient, cfg *clientConfig) {
+		cfg.middlewares = append(cfg.middlewares, mws...)
+	}
+}
+
+// WithHistory 为客户端启用调用历史记录，每次 CreatePrototype 都会写入 store。
+// store 为 nil 时退化为进程内的 history.MemoryStore。
+func WithHistory(store history.Store) ClientOption {
+	return func(c *AIAgentClient, cfg *clientConfig) {
+		if store == nil {
+			store = history.NewMemoryStore()
+		}
+		cfg.historyStore = store
+	}
+}
+
+// WithPrototypeCache 为客户端启用 DownloadPrototype 的本地产物缓存，指向同一个
+// prototype_url 的重复请求会直接命中缓存，不再重新下载
+func WithPrototypeCache(cache *PrototypeCache) ClientOption {
+	return func(c *AIAgentClient, cfg *clientConfig) {
+		cfg.prototypeCache = cache
+	}
+}
+
+// RetryConfig 配置指数退避重试
+type RetryConfig struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// WithRetry 对 5xx 响应与网络错误做带抖动的指数退避重试
+func WithRetry(cfg RetryConfig) Middleware {
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = 10 * time.Second
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var resp *http.Response
+			var err error
+
+			for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+				if attempt > 0 && req.GetBody != nil {
+					body, bodyErr := req.GetBody()
+					if bodyErr != nil {
+						return nil, fmt.Errorf("重试前重置请求体失败: %w", bodyErr)
+					}
+					req.Body = body
+				}
+
+				resp, err = next.RoundTrip(req)
+				if err == nil && resp.StatusCode < 500 {
+					return resp, nil
+				}
+				if attempt == cfg.MaxRetries {
+					break
+				}
+				if resp != nil {
+					resp.Body.Close()
+				}
+				if waitErr := sleepWithJitter(req.Context(), backoffDelay(cfg.BaseDelay, cfg.MaxDelay, attempt)); waitErr != nil {
+					return nil, waitErr
+				}
+			}
+			return resp, err
+		})
+	}
+}
+
+// backoffDelay 计算第 attempt 次重试前的基础退避时长（指数增长，封顶 maxDelay）
+func backoffDelay(base, maxDelay time.Duration, attempt int) time.Duration {
+	delay := base * time.Duration(math.Pow(2, float64(attempt)))
+	if delay > maxDelay || delay <= 0 {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// sleepWithJitter 等待 [delay/2, delay] 区间内的一个随机时长，期间可被 ctx 取消
+func sleepWithJitter(ctx context.Context, delay time.Duration) error {
+	jitter, _ := rand.Int(rand.Reader, big.NewInt(int64(delay)/2+1))
+	wait := delay/2 + time.Duration(jitter.Int64())
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tokenBucket 是一个简单的令牌桶限流器
+type tokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	capacity   float64
+	refillRate float64 // tokens/second
+	lastRefill time.Time
+}
+
+func newTokenBucket(rps float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		tokens:     float64(burst),
+		capacity:   float64(burst),
+		refillRate: rps,
+		lastRefill: time.Now(),
+	}
+}
+
+func (b *tokenBucket) take(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.lastRefill).Seconds()*b.refillRate)
+		b.lastRefill = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		missing := 1 - b.tokens
+		waitFor := time.Duration(missing/b.refillRate*1000) * time.Millisecond
+		b.mu.Unlock()
+
+		timer := time.NewTimer(waitFor)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// WithRateLimit 使用令牌桶限制请求速率（rps 为每秒令牌数，burst 为桶容量）
+func WithRateLimit(rps float64, burst int) Middleware {
+	bucket := newTokenBucket(rps, burst)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := bucket.take(req.Context()); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// circuitState 熔断器状态
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker 是一个 closed/open/half-open 三态熔断器，基于失败率触发；失败率按上次
+// 状态切换以来累计的请求数计算，不是滑动窗口
+type circuitBreaker struct {
+	mu               sync.Mutex
+	state            circuitState
+	failureThreshold float64
+	minRequests      int
+	openDuration     time.Duration
+	openedAt         time.Time
+	probing          bool // half-open状态下是否已经有一个试探请求在途，确保半开时只放行一个请求
+
+	requests int
+	failures int
+}
+
+// WithCircuitBreaker 当上次状态切换以来累计的失败率达到 failureThreshold（且样本数达到 minRequests）时
+// 打开熔断，openDuration 后进入半开状态试探性放行一个请求
+func WithCircuitBreaker(failureThreshold float64, minRequests int, openDuration time.Duration) Middleware {
+	cb := &circuitBreaker{
+		failureThreshold: failureThreshold,
+		minRequests:      minRequests,
+		openDuration:     openDuration,
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !cb.allow() {
+				return nil, fmt.Errorf("熔断器已打开，拒绝请求: %s", req.URL.Path)
+			}
+
+			resp, err := next.RoundTrip(req)
+			cb.record(err != nil || (resp != nil && resp.StatusCode >= 500))
+			return resp, err
+		})
+	}
+}
+
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if time.Since(cb.openedAt) >= cb.openDuration {
+			cb.state = circuitHalfOpen
+			cb.probing = true
+			return true
+		}
+		return false
+	case circuitHalfOpen:
+		// half-open 只放行一个试探请求，其余并发请求在 record() 给出结论前都先拒绝
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *circuitBreaker) record(failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitHalfOpen {
+		cb.probing = false
+		if failed {
+			cb.state = circuitOpen
+			cb.openedAt = time.Now()
+			cb.requests, cb.failures = 0, 0
+			return
+		}
+		cb.state = circuitClosed
+		cb.requests, cb.failures = 0, 0
+		return
+	}
+
+	cb.requests++
+	if failed {
+		cb.failures++
+	}
+	if cb.requests >= cb.minRequests && float64(cb.failures)/float64(cb.requests) >= cb.failureThreshold {
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+		cb.requests, cb.failures = 0, 0
+	}
+}
+
+// TokenProvider 返回当前应使用的 bearer/JWT token，供 WithBearerAuth 在每次请求前刷新
+type TokenProvider func(ctx context.Context) (string, error)
+
+// WithBearerAuth 为每个请求注入 Authorization: Bearer <token>，token 在每次请求前通过
+// provider 获取，provider 自身负责缓存与过期刷新
+func WithBearerAuth(provider TokenProvider) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := provider(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("获取鉴权token失败: %w", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// WithTracing 为每个请求创建一个 OpenTelemetry span，并把 trace context 注入请求头
+func WithTracing(tracerName string) Middleware {
+	tracer := otel.Tracer(tracerName)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), req.Method+" "+req.URL.Path,
+				trace.WithAttributes(
+					attribute.String("http.method", req.Method),
+					attribute.String("http.url", req.URL.String()),
+				),
+			)
+			defer span.End()
+
+			req = req.WithContext(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				span.RecordError(err)
+			} else {
+				span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+			}
+			return resp, err
+		})
+	}
+}
+
+// clientMetrics 聚合 Prometheus 指标：按 endpoint 维度统计请求数与延迟分布
+type clientMetrics struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+func newClientMetrics(registerer prometheus.Registerer) *clientMetrics {
+	m := &clientMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ai_agent_client_requests_total",
+			Help: "AIAgentClient请求总数，按endpoint和status分类",
+		}, []string{"endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ai_agent_client_request_duration_seconds",
+			Help:    "AIAgentClient请求延迟分布，按endpoint分类",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+	}
+	registerer.MustRegister(m.requests, m.latency)
+	return m
+}
+
+// WithMetrics 为每个请求记录 Prometheus 计数器和延迟直方图
+func WithMetrics(registerer prometheus.Registerer) Middleware {
+	metrics := newClientMetrics(registerer)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			metrics.latency.WithLabelValues(req.URL.Path).Observe(time.Since(start).Seconds())
+
+			status := "error"
+			if resp != nil {
+				status = fmt.Sprintf("%d", resp.StatusCode)
+			}
+			metrics.requests.WithLabelValues(req.URL.Path, status).Inc()
+			return resp, err
+		})
+	}
+}
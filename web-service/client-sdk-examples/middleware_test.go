@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{2, 400 * time.Millisecond},
+		{10, time.Second}, // 指数增长封顶到 maxDelay
+	}
+	for _, c := range cases {
+		if got := backoffDelay(100*time.Millisecond, time.Second, c.attempt); got != c.want {
+			t.Errorf("backoffDelay(attempt=%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestTokenBucketTakeWithinBurst(t *testing.T) {
+	b := newTokenBucket(1000, 2)
+	ctx := context.Background()
+
+	if err := b.take(ctx); err != nil {
+		t.Fatalf("第一次take: %v", err)
+	}
+	if err := b.take(ctx); err != nil {
+		t.Fatalf("第二次take（burst范围内）: %v", err)
+	}
+}
+
+func TestTokenBucketTakeCanceled(t *testing.T) {
+	b := newTokenBucket(0.001, 1) // 几乎不填充，burst耗尽后基本不会再发到令牌
+	ctx := context.Background()
+	if err := b.take(ctx); err != nil {
+		t.Fatalf("第一次take应该直接消耗burst成功: %v", err)
+	}
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.take(canceledCtx); err == nil {
+		t.Fatal("桶已空且ctx已取消时，take应该返回error")
+	}
+}
+
+func TestCircuitBreakerOpensAfterFailureThreshold(t *testing.T) {
+	cb := &circuitBreaker{failureThreshold: 0.5, minRequests: 2, openDuration: time.Minute}
+
+	cb.record(true)
+	if !cb.allow() {
+		t.Fatal("未达到minRequests前不应该拒绝请求")
+	}
+	cb.record(true)
+
+	if cb.allow() {
+		t.Fatal("失败率达到阈值后应该拒绝请求")
+	}
+	if cb.state != circuitOpen {
+		t.Fatalf("熔断后状态应该是open，got %v", cb.state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsOnlySingleProbe(t *testing.T) {
+	cb := &circuitBreaker{
+		failureThreshold: 0.5,
+		minRequests:      1,
+		openDuration:     time.Millisecond,
+		state:            circuitOpen,
+		openedAt:         time.Now().Add(-time.Hour), // 早已超过 openDuration
+	}
+
+	if !cb.allow() {
+		t.Fatal("openDuration过后应该放行一个试探请求")
+	}
+	if cb.state != circuitHalfOpen {
+		t.Fatalf("放行试探请求后状态应该是half-open，got %v", cb.state)
+	}
+
+	for i := 0; i < 5; i++ {
+		if cb.allow() {
+			t.Fatal("half-open状态下，试探请求的结果揭晓前不应该再放行任何并发请求")
+		}
+	}
+
+	cb.record(false)
+	if cb.state != circuitClosed {
+		t.Fatalf("试探请求成功后应该恢复closed，got %v", cb.state)
+	}
+	if !cb.allow() {
+		t.Fatal("closed状态应该放行请求")
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbeFailureReopens(t *testing.T) {
+	cb := &circuitBreaker{
+		failureThreshold: 0.5,
+		minRequests:      1,
+		openDuration:     time.Millisecond,
+		state:            circuitOpen,
+		openedAt:         time.Now().Add(-time.Hour),
+	}
+
+	if !cb.allow() {
+		t.Fatal("openDuration过后应该放行一个试探请求")
+	}
+
+	cb.record(true)
+	if cb.state != circuitOpen {
+		t.Fatalf("试探请求失败后应该重新打开，got %v", cb.state)
+	}
+	if cb.allow() {
+		t.Fatal("刚重新打开的熔断器不应该立刻放行")
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+/*
+交互式原型设计会话 (WebShell)
+
+NewDesignSession 在 /api/v1/prototype_design/session 上开一条 WebSocket 连接，
+让调用方在不重发完整 requirement 的前提下持续追加反馈（"把侧边栏改成可折叠"、
+"配色改成深色"），服务端按 session ID 保持对话与已生成产物的状态。
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// AgentMsgType 描述一条从 Agent 侧收到的会话消息类型
+type AgentMsgType string
+
+const (
+	AgentMsgText      AgentMsgType = "text"
+	AgentMsgToolCall  AgentMsgType = "tool_call"
+	AgentMsgFileWrite AgentMsgType = "file_write"
+	AgentMsgPreview   AgentMsgType = "preview"
+	AgentMsgError     AgentMsgType = "error"
+)
+
+// AgentMsg 是 Session.Recv 返回的一条结构化会话消息，调用方可以据此在 TUI/Web 前端渲染
+type AgentMsg struct {
+	Type AgentMsgType    `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// sessionKeepAlive 是 ping 的发送间隔
+const sessionKeepAlive = 30 * time.Second
+
+// sessionReconnectDelay 是连接意外断开后尝试恢复前的等待时间
+const sessionReconnectDelay = 2 * time.Second
+
+// Session 是一次双向的交互式设计会话，Send 发送用户反馈，Recv 接收 Agent 的进度消息
+type Session struct {
+	client    *AIAgentClient
+	sessionID string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+
+	// writeMu 串行化所有写向 conn 的调用（Send 的 WriteJSON、keepAlivePump 的
+	// WriteControl），gorilla/websocket 的一个连接只允许同时存在一个写者
+	writeMu sync.Mutex
+
+	incoming chan AgentMsg
+}
+
+// NewDesignSession 建立一条交互式设计会话，initialRequirement 作为首轮请求发送给 Agent
+func (c *AIAgentClient) NewDesignSession(ctx context.Context, initialRequirement string) (*Session, error) {
+	sessionCtx, cancel := context.WithCancel(ctx)
+	s := &Session{
+		client:   c,
+		ctx:      sessionCtx,
+		cancel:   cancel,
+		incoming: make(chan AgentMsg, 16),
+	}
+
+	if err := s.dial(""); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if err := s.Send(initialRequirement); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	go s.readPump()
+	go s.keepAlivePump()
+
+	return s, nil
+}
+
+// dial 建立（或在重连时重建）底层 WebSocket 连接；resumeSessionID 非空时附带在握手请求里恢复会话
+func (s *Session) dial(resumeSessionID string) error {
+	wsURL, err := toWebSocketURL(s.client.BaseURL) // ws(s)://host:port
+	if err != nil {
+		return err
+	}
+	wsURL += "/api/v1/prototype_design/session"
+	if resumeSessionID != "" {
+		wsURL += "?session_id=" + resumeSessionID
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(s.ctx, wsURL, nil)
+	if err != nil {
+		return fmt.Errorf("建立会话连接失败: %w", err)
+	}
+
+	s.mu.Lock()
+	oldConn := s.conn
+	s.conn = conn
+	s.mu.Unlock()
+
+	if oldConn != nil {
+		_ = oldConn.Close()
+	}
+	return nil
+}
+
+// toWebSocketURL 把客户端的 http(s) BaseURL 转换成 ws(s) scheme
+func toWebSocketURL(baseURL string) (string, error) {
+	switch {
+	case len(baseURL) >= 5 && baseURL[:5] == "https":
+		return "wss" + baseURL[5:], nil
+	case len(baseURL) >= 4 && baseURL[:4] == "http":
+		return "ws" + baseURL[4:], nil
+	default:
+		return "", fmt.Errorf("无法识别的 BaseURL: %s", baseURL)
+	}
+}
+
+// Send 向会话发送一条用户消息（初始 requirement 或后续的迭代反馈）
+func (s *Session) Send(userMsg string) error {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return fmt.Errorf("会话尚未建立连接")
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return conn.WriteJSON(map[string]string{"message": userMsg})
+}
+
+// Recv 阻塞等待下一条 Agent 消息，会话结束（Close 或 ctx 被取消）时返回 error；
+// 连接意外断开时后台会持续重连，Recv 继续等待而不返回 error
+func (s *Session) Recv() (AgentMsg, error) {
+	select {
+	case msg, ok := <-s.incoming:
+		if !ok {
+			return AgentMsg{}, fmt.Errorf("会话已关闭")
+		}
+		return msg, nil
+	case <-s.ctx.Done():
+		return AgentMsg{}, s.ctx.Err()
+	}
+}
+
+// Close 主动结束会话并释放底层连接
+func (s *Session) Close() error {
+	s.cancel()
+
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// readPump 持续读取服务端消息，记录 session_id 以便断线后恢复，断线时自动重连直到 ctx 被取消
+func (s *Session) readPump() {
+	defer close(s.incoming)
+
+	conn := s.currentConn()
+	conn.SetPongHandler(func(string) error { return nil })
+
+	for {
+		var msg AgentMsg
+		err := conn.ReadJSON(&msg)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			if !s.reconnect() {
+				return
+			}
+			conn = s.currentConn()
+			conn.SetPongHandler(func(string) error { return nil })
+			continue
+		}
+
+		if msg.Type == "session_id" {
+			_ = json.Unmarshal(msg.Data, &s.sessionID)
+			continue
+		}
+
+		select {
+		case s.incoming <- msg:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// keepAlivePump 周期性发送 ping 帧维持连接；服务端回的 pong 不参与存活判断
+// （PongHandler 是空操作，也没有设置读超时）
+func (s *Session) keepAlivePump() {
+	ticker := time.NewTicker(sessionKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			conn := s.currentConn()
+			s.writeMu.Lock()
+			_ = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second))
+			s.writeMu.Unlock()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// reconnect 在连接意外中断时持续尝试恢复同一个 session_id，直到重连成功或 ctx 被取消
+func (s *Session) reconnect() bool {
+	for {
+		select {
+		case <-time.After(sessionReconnectDelay):
+		case <-s.ctx.Done():
+			return false
+		}
+
+		if err := s.dial(s.sessionID); err == nil {
+			return true
+		}
+	}
+}
+
+func (s *Session) currentConn() *websocket.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn
+}
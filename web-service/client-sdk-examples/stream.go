@@ -0,0 +1,162 @@
+package main
+
+/*
+原型设计流式生成 (SSE)
+
+CreatePrototypeStream 通过 Server-Sent Events 连接 /api/v1/prototype_design/design/stream
+端点，随着 Agent 的思考、工具调用、局部 HTML 产出等进度持续推送 DesignEvent，
+调用方可以边收边渲染，并通过 ctx 随时取消，而不必等待完整阻塞响应。
+*/
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DesignEventType 流式设计事件类型
+type DesignEventType string
+
+const (
+	DesignEventThinking       DesignEventType = "thinking"
+	DesignEventToolCall       DesignEventType = "tool_call"
+	DesignEventPartialHTML    DesignEventType = "partial_html"
+	DesignEventPrototypeReady DesignEventType = "prototype_ready"
+	DesignEventError          DesignEventType = "error"
+)
+
+// DesignEvent 流式设计过程中的单条事件
+type DesignEvent struct {
+	ID   string          `json:"id,omitempty"`
+	Type DesignEventType `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// streamReconnectDelay 是连接中断后尝试重连前的等待时间
+const streamReconnectDelay = 2 * time.Second
+
+// CreatePrototypeStream 以流式方式创建原型设计，通过返回的 channel 持续接收进度事件。
+// channel 会在生成结束（收到服务端发来的 prototype_ready/error 事件）或 ctx 被取消时关闭。
+// 连接失败或意外断开时，会先推送一条客户端生成的 error 事件（data 为 {"message": ...}），
+// 等待 streamReconnectDelay 后带上 Last-Event-ID 重连，这种情况下 channel 不会关闭。
+func (c *AIAgentClient) CreatePrototypeStream(ctx context.Context, req DesignRequest) (<-chan DesignEvent, error) {
+	if req.Style == "" {
+		req.Style = "现代简约"
+	}
+
+	events := make(chan DesignEvent)
+
+	go func() {
+		defer close(events)
+
+		lastEventID := ""
+		for {
+			err := c.streamOnce(ctx, req, lastEventID, events, &lastEventID)
+			if err == nil {
+				return
+			}
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return
+			}
+
+			select {
+			case events <- DesignEvent{Type: DesignEventError, Data: json.RawMessage(fmt.Sprintf(`{"message":%q}`, err.Error()))}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-time.After(streamReconnectDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// streamOnce 建立一次 SSE 连接并持续解析事件，直到连接结束或收到终止事件。
+// lastEventID 用于 Last-Event-ID 断线重连；收到的最新事件 ID 会写回 *outLastEventID。
+func (c *AIAgentClient) streamOnce(ctx context.Context, req DesignRequest, lastEventID string, events chan<- DesignEvent, outLastEventID *string) error {
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("JSON序列化失败: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/api/v1/prototype_design/design/stream", strings.NewReader(string(jsonData)))
+	if err != nil {
+		return fmt.Errorf("创建流式请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		httpReq.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := c.Client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("建立流式连接失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("流式请求失败 [%d]", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var eventName string
+	var dataLines []string
+
+	flush := func() (bool, error) {
+		if len(dataLines) == 0 && eventName == "" {
+			return false, nil
+		}
+		data := strings.Join(dataLines, "\n")
+		evt := DesignEvent{Type: DesignEventType(eventName), Data: json.RawMessage(data)}
+		eventName, dataLines = "", nil
+
+		select {
+		case events <- evt:
+		case <-ctx.Done():
+			return true, ctx.Err()
+		}
+
+		if evt.Type == DesignEventPrototypeReady || evt.Type == DesignEventError {
+			return true, nil
+		}
+		return false, nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			done, err := flush()
+			if done {
+				return err
+			}
+		case strings.HasPrefix(line, "id:"):
+			*outLastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "event:"):
+			eventName = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取流式响应失败: %w", err)
+	}
+
+	// 服务端正常关闭连接但未发送终止事件，视为连接中断，触发重连。
+	return fmt.Errorf("流式连接意外关闭")
+}
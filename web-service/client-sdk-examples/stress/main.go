@@ -0,0 +1,427 @@
+package main
+
+/*
+AI Agent Web Service - 压测工具
+
+对 web-service 的 /health 与 /api/v1/prototype_design/design 等接口发起可配置的
+并发压测，统计 p50/p90/p99 延迟、QPS、按 HTTP 状态码分类的错误数与成功率，
+用于评估集群内部署规模与发现性能回归。
+
+-endpoint mixed 时可以用 -weight-health/-weight-info/-weight-design 分别调整每个
+接口的抽样权重；更复杂的场景（自定义 path、自定义 requirement/style、混合多个非内置
+接口）写成一个 JSON 场景文件（scenario 结构体的数组，字段见下方 scenario 定义），通过
+-scenarios-file 加载，多次压测可以复用同一份文件重放。-output text 时默认会在 stderr
+打印一行随请求进度刷新的实时统计，用 -progress=false 关闭。
+
+使用示例:
+    go run ./stress -base-url http://web-service.ai-agents.svc.cluster.local:8000 \
+        -endpoint design -concurrency 20 -total 2000 -output json
+
+    go run ./stress -scenarios-file scenarios.json -concurrency 20 -total 2000
+*/
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// scenario 描述一个压测场景：请求哪个接口、用什么负载，以及抽样权重
+type scenario struct {
+	Name        string `json:"name"`
+	Weight      int    `json:"weight"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Requirement string `json:"requirement,omitempty"`
+	Style       string `json:"style,omitempty"`
+}
+
+// result 记录一次请求的结果
+type result struct {
+	scenario   string
+	statusCode int
+	err        error
+	latency    time.Duration
+	start      time.Time
+	end        time.Time
+}
+
+// report 汇总压测结果，用于 TTY 展示与 JSON/CSV 输出
+type report struct {
+	Scenario     string         `json:"scenario"`
+	Requests     int            `json:"requests"`
+	Success      int            `json:"success"`
+	SuccessRate  float64        `json:"success_rate"`
+	QPS          float64        `json:"qps"`
+	P50Ms        float64        `json:"p50_ms"`
+	P90Ms        float64        `json:"p90_ms"`
+	P99Ms        float64        `json:"p99_ms"`
+	ErrorsByCode map[string]int `json:"errors_by_code"`
+}
+
+func main() {
+	baseURL := flag.String("base-url", "http://web-service.ai-agents.svc.cluster.local:8000", "被压测服务的地址")
+	concurrency := flag.Int("concurrency", 10, "并发 worker 数")
+	total := flag.Int("total", 1000, "总请求数（0 表示改用 -duration 控制）")
+	duration := flag.Duration("duration", 0, "压测持续时间（优先于 -total，0 表示不启用）")
+	rampUp := flag.Duration("ramp-up", 0, "并发爬坡时间，worker 会在此时间内均匀启动")
+	endpoint := flag.String("endpoint", "health", "压测场景: health | info | design | mixed（被 -scenarios-file 覆盖）")
+	requirement := flag.String("requirement", "用户管理界面", "design 场景使用的 requirement")
+	style := flag.String("style", "现代简约风格", "design 场景使用的 style")
+	weightHealth := flag.Int("weight-health", 1, "mixed 场景下 health 的抽样权重")
+	weightInfo := flag.Int("weight-info", 1, "mixed 场景下 info 的抽样权重")
+	weightDesign := flag.Int("weight-design", 1, "mixed 场景下 design 的抽样权重")
+	scenariosFile := flag.String("scenarios-file", "", "JSON 场景文件路径，设置后取代 -endpoint 等内置场景，可用于多次重放同一组请求模板")
+	output := flag.String("output", "text", "报告格式: text | json | csv")
+	timeout := flag.Duration("timeout", 30*time.Second, "单次请求超时时间")
+	progress := flag.Bool("progress", true, "是否在 stderr 实时刷新压测进度（仅 -output text 时生效）")
+	flag.Parse()
+
+	var scenarios []scenario
+	if *scenariosFile != "" {
+		loaded, err := loadScenariosFile(*scenariosFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "加载场景文件失败: %v\n", err)
+			os.Exit(1)
+		}
+		scenarios = loaded
+	} else {
+		scenarios = buildScenarios(*endpoint, *requirement, *style, *weightHealth, *weightInfo, *weightDesign)
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	results := run(client, *baseURL, scenarios, *concurrency, *total, *duration, *rampUp, *progress && *output == "text")
+
+	reports := summarize(results)
+	if err := printReport(reports, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "输出报告失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// buildScenarios 根据 -endpoint 选择构造出要压测的场景集合；mixed 会按
+// weightHealth/weightInfo/weightDesign 把三个内置接口合并成一组按权重抽样的场景
+func buildScenarios(endpoint, requirement, style string, weightHealth, weightInfo, weightDesign int) []scenario {
+	health := scenario{Name: "health", Weight: weightHealth, Method: http.MethodGet, Path: "/health"}
+	info := scenario{Name: "info", Weight: weightInfo, Method: http.MethodGet, Path: "/api/v1/info"}
+	design := scenario{Name: "design", Weight: weightDesign, Method: http.MethodPost, Path: "/api/v1/prototype_design/design", Requirement: requirement, Style: style}
+
+	switch endpoint {
+	case "info":
+		return []scenario{info}
+	case "design":
+		return []scenario{design}
+	case "mixed":
+		return []scenario{health, info, design}
+	default:
+		return []scenario{health}
+	}
+}
+
+// loadScenariosFile 从一个 JSON 文件加载场景模板（scenario 数组），用于自定义/混合
+// 压测场景，并支持在多次压测之间原样重放同一份请求模板
+func loadScenariosFile(path string) ([]scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取场景文件失败: %w", err)
+	}
+
+	var scenarios []scenario
+	if err := json.Unmarshal(data, &scenarios); err != nil {
+		return nil, fmt.Errorf("解析场景文件失败: %w", err)
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("场景文件 %s 不包含任何场景", path)
+	}
+	return scenarios, nil
+}
+
+// run 按配置的并发、总量/持续时间与爬坡策略发起压测，返回全部请求的原始结果。
+// showProgress 为 true 时会在 stderr 打印一行随请求完成持续刷新的实时统计。
+func run(client *http.Client, baseURL string, scenarios []scenario, concurrency, total int, duration, rampUp time.Duration, showProgress bool) []result {
+	var wg sync.WaitGroup
+	resultsCh := make(chan result, concurrency*2)
+	done := make(chan struct{})
+	var issued, succeeded, failed int64
+
+	weighted := expandByWeight(scenarios)
+
+	// stop 返回 false 时已原子地占用了一次发送名额（issued+1），多个 worker 并发时
+	// -total 模式也不会多发请求
+	stop := func() bool {
+		if duration > 0 {
+			select {
+			case <-done:
+				return true
+			default:
+				atomic.AddInt64(&issued, 1)
+				return false
+			}
+		}
+		for {
+			n := atomic.LoadInt64(&issued)
+			if int(n) >= total {
+				return true
+			}
+			if atomic.CompareAndSwapInt64(&issued, n, n+1) {
+				return false
+			}
+		}
+	}
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		startDelay := time.Duration(0)
+		if rampUp > 0 && concurrency > 1 {
+			startDelay = rampUp * time.Duration(w) / time.Duration(concurrency)
+		}
+
+		go func(workerStartDelay time.Duration) {
+			defer wg.Done()
+			time.Sleep(workerStartDelay)
+
+			for !stop() {
+				sc := weighted[rand.Intn(len(weighted))]
+				resultsCh <- doRequest(client, baseURL, sc)
+			}
+		}(startDelay)
+	}
+
+	if duration > 0 {
+		go func() {
+			time.Sleep(duration)
+			close(done)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
+
+	progressDone := make(chan struct{})
+	if showProgress {
+		go printProgress(&issued, &succeeded, &failed, progressDone)
+	} else {
+		close(progressDone)
+	}
+
+	var all []result
+	for r := range resultsCh {
+		all = append(all, r)
+		if r.err != nil || r.statusCode >= 400 {
+			atomic.AddInt64(&failed, 1)
+		} else {
+			atomic.AddInt64(&succeeded, 1)
+		}
+	}
+
+	if showProgress {
+		close(progressDone)
+		fmt.Fprintln(os.Stderr)
+	}
+	return all
+}
+
+// printProgress 每隔一小段时间把当前已发送/成功/失败的请求数刷新打印到同一行 stderr，
+// 直到 done 被关闭，给长时间运行的压测提供一个可以盯着看的实时进度
+func printProgress(issued, succeeded, failed *int64, done <-chan struct{}) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	start := time.Now()
+	render := func() {
+		elapsed := time.Since(start).Seconds()
+		i := atomic.LoadInt64(issued)
+		qps := 0.0
+		if elapsed > 0 {
+			qps = float64(i) / elapsed
+		}
+		fmt.Fprintf(os.Stderr, "\r已发送: %d  成功: %d  失败: %d  QPS: %.1f  耗时: %.1fs",
+			i, atomic.LoadInt64(succeeded), atomic.LoadInt64(failed), qps, elapsed)
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			render()
+		case <-done:
+			render()
+			return
+		}
+	}
+}
+
+// expandByWeight 按场景权重展开成一个可均匀随机抽取的列表
+func expandByWeight(scenarios []scenario) []scenario {
+	var weighted []scenario
+	for _, sc := range scenarios {
+		weight := sc.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for i := 0; i < weight; i++ {
+			weighted = append(weighted, sc)
+		}
+	}
+	return weighted
+}
+
+// doRequest 发起单次请求并记录延迟、状态码与错误
+func doRequest(client *http.Client, baseURL string, sc scenario) result {
+	method := strings.ToUpper(sc.Method)
+
+	var body []byte
+	if method == http.MethodPost {
+		payload, _ := json.Marshal(map[string]string{
+			"requirement": sc.Requirement,
+			"style":       sc.Style,
+		})
+		body = payload
+	}
+
+	req, err := http.NewRequest(method, baseURL+sc.Path, bytes.NewReader(body))
+	if err != nil {
+		return result{scenario: sc.Name, err: err}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	end := time.Now()
+	latency := end.Sub(start)
+	if err != nil {
+		return result{scenario: sc.Name, latency: latency, start: start, end: end, err: err}
+	}
+	defer resp.Body.Close()
+
+	return result{scenario: sc.Name, latency: latency, start: start, end: end, statusCode: resp.StatusCode}
+}
+
+// summarize 按场景分组计算延迟分位数、QPS、成功率与错误码分布
+func summarize(results []result) []report {
+	byScenario := map[string][]result{}
+	for _, r := range results {
+		byScenario[r.scenario] = append(byScenario[r.scenario], r)
+	}
+
+	var reports []report
+	for name, rs := range byScenario {
+		latencies := make([]time.Duration, 0, len(rs))
+		errorsByCode := map[string]int{}
+		success := 0
+		var minStart, maxEnd time.Time
+
+		for _, r := range rs {
+			latencies = append(latencies, r.latency)
+			if minStart.IsZero() || r.start.Before(minStart) {
+				minStart = r.start
+			}
+			if r.end.After(maxEnd) {
+				maxEnd = r.end
+			}
+			switch {
+			case r.err != nil:
+				errorsByCode["network_error"]++
+			case r.statusCode >= 200 && r.statusCode < 400:
+				success++
+			default:
+				errorsByCode[fmt.Sprintf("%d", r.statusCode)]++
+			}
+		}
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		elapsed := maxEnd.Sub(minStart)
+		qps := 0.0
+		if elapsed > 0 {
+			qps = float64(len(rs)) / elapsed.Seconds()
+		}
+
+		reports = append(reports, report{
+			Scenario:     name,
+			Requests:     len(rs),
+			Success:      success,
+			SuccessRate:  ratio(success, len(rs)),
+			QPS:          qps,
+			P50Ms:        percentileMs(latencies, 0.50),
+			P90Ms:        percentileMs(latencies, 0.90),
+			P99Ms:        percentileMs(latencies, 0.99),
+			ErrorsByCode: errorsByCode,
+		})
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Scenario < reports[j].Scenario })
+	return reports
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+func percentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return float64(sorted[idx].Microseconds()) / 1000.0
+}
+
+// printReport 按 -output 选择的格式打印报告：text 用于 TTY 实时查看，json/csv 用于 CI 趋势追踪
+func printReport(reports []report, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		defer w.Flush()
+		if err := w.Write([]string{"scenario", "requests", "success", "success_rate", "qps", "p50_ms", "p90_ms", "p99_ms"}); err != nil {
+			return err
+		}
+		for _, r := range reports {
+			row := []string{
+				r.Scenario,
+				fmt.Sprintf("%d", r.Requests),
+				fmt.Sprintf("%d", r.Success),
+				fmt.Sprintf("%.4f", r.SuccessRate),
+				fmt.Sprintf("%.2f", r.QPS),
+				fmt.Sprintf("%.2f", r.P50Ms),
+				fmt.Sprintf("%.2f", r.P90Ms),
+				fmt.Sprintf("%.2f", r.P99Ms),
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		for _, r := range reports {
+			fmt.Printf("场景: %s\n", r.Scenario)
+			fmt.Printf("  请求数: %d  成功: %d  成功率: %.2f%%\n", r.Requests, r.Success, r.SuccessRate*100)
+			fmt.Printf("  QPS: %.2f\n", r.QPS)
+			fmt.Printf("  延迟 p50/p90/p99 (ms): %.2f / %.2f / %.2f\n", r.P50Ms, r.P90Ms, r.P99Ms)
+			if len(r.ErrorsByCode) > 0 {
+				fmt.Printf("  错误分布: %v\n", r.ErrorsByCode)
+			}
+			fmt.Println()
+		}
+		return nil
+	}
+}
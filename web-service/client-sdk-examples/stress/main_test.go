@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPercentileMs(t *testing.T) {
+	sorted := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		30 * time.Millisecond,
+		40 * time.Millisecond,
+		50 * time.Millisecond,
+	}
+
+	if got := percentileMs(nil, 0.5); got != 0 {
+		t.Fatalf("空切片应该返回0，got %v", got)
+	}
+	if got := percentileMs(sorted, 0); got != 10 {
+		t.Fatalf("p0 = %v, want 10", got)
+	}
+	if got := percentileMs(sorted, 1); got != 50 {
+		t.Fatalf("p100 = %v, want 50", got)
+	}
+	if got := percentileMs(sorted, 0.5); got != 30 {
+		t.Fatalf("p50 = %v, want 30", got)
+	}
+}
+
+func TestRatio(t *testing.T) {
+	if got := ratio(0, 0); got != 0 {
+		t.Fatalf("ratio(0,0) = %v, want 0", got)
+	}
+	if got := ratio(1, 4); got != 0.25 {
+		t.Fatalf("ratio(1,4) = %v, want 0.25", got)
+	}
+}
+
+func TestExpandByWeight(t *testing.T) {
+	scenarios := []scenario{
+		{Name: "a", Weight: 2},
+		{Name: "b", Weight: 1},
+		{Name: "c", Weight: 0}, // <=0 按 1 处理
+	}
+
+	weighted := expandByWeight(scenarios)
+	counts := map[string]int{}
+	for _, sc := range weighted {
+		counts[sc.Name]++
+	}
+
+	if counts["a"] != 2 || counts["b"] != 1 || counts["c"] != 1 {
+		t.Fatalf("按权重展开后的计数不对: %+v", counts)
+	}
+}
+
+func TestDoRequestMethodIsCaseInsensitive(t *testing.T) {
+	var gotMethod string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sc := scenario{
+		Name:        "design",
+		Method:      "post", // 场景文件里最常见的写法：小写
+		Path:        "/api/v1/prototype_design/design",
+		Requirement: "用户管理界面",
+		Style:       "现代简约",
+	}
+
+	res := doRequest(server.Client(), server.URL, sc)
+	if res.err != nil {
+		t.Fatalf("doRequest: %v", res.err)
+	}
+	if gotMethod != http.MethodPost {
+		t.Fatalf("小写method应该被规范成POST发送，实际收到 %q", gotMethod)
+	}
+	if len(gotBody) == 0 {
+		t.Fatal("POST场景应该带上requirement/style请求体，实际收到空body")
+	}
+}